@@ -0,0 +1,165 @@
+// Package bridge provides a pluggable relay layer that fans WhatsApp
+// messages out to remote chat networks (and back) without main.go needing
+// to know about any particular protocol.
+package bridge
+
+import "fmt"
+
+// Message is the protocol-agnostic payload passed between WhatsApp and a
+// remote backend. Backends translate it to and from their own wire format.
+type Message struct {
+	// Channel is the remote channel/room identifier, as configured in the
+	// gateway mapping (e.g. "#general" for IRC/Discord, "!room:home" for Matrix).
+	Channel string
+	// Username is the display name of the sender, shown on the remote side.
+	Username string
+	// Text is the plain-text message body.
+	Text string
+	// Account identifies the protocol the message came from, e.g. "matrix"
+	// or "whatsapp"; it does not name the configured backend instance.
+	Account string
+}
+
+// Bridger is implemented by every remote chat backend. It mirrors the shape
+// matterbridge uses for its protocol adapters: a backend connects once,
+// joins whatever channels the gateway config maps to it, and then just
+// pushes/pulls Message values.
+type Bridger interface {
+	// Connect establishes the backend's session (login, websocket, IRC
+	// registration, etc). It must be safe to call Receive only after
+	// Connect returns nil.
+	Connect() error
+
+	// JoinChannel joins or subscribes to a remote channel so messages can
+	// flow in both directions for it.
+	JoinChannel(channel string) error
+
+	// Send delivers a message originating elsewhere (WhatsApp or another
+	// bridge) into the backend's channel.
+	Send(msg Message) error
+
+	// Receive returns a channel of messages arriving from the backend. The
+	// channel is never closed, including after Disconnect: the backend's
+	// producer goroutine isn't guaranteed to have exited by the time
+	// Disconnect returns, so closing it here would risk a send on a closed
+	// channel. Callers that range over Receive must stop doing so via some
+	// other signal, e.g. Relay.Done, rather than relying on the channel
+	// closing.
+	Receive() <-chan Message
+
+	// Disconnect tears down the backend's session.
+	Disconnect() error
+}
+
+// Gateway maps a single WhatsApp chat JID to one or more remote channels
+// across any number of configured backends, following the [[gateway]]
+// section layout in Config.
+type Gateway struct {
+	Name        string
+	WhatsAppJID string
+	Routes      []Route
+}
+
+// Route is one leg of a Gateway: a backend account plus the channel on it.
+type Route struct {
+	Account string
+	Channel string
+}
+
+// Relay owns the configured backends and routes messages between WhatsApp
+// and them according to the gateway mapping.
+type Relay struct {
+	backends map[string]Bridger
+	gateways []Gateway
+	done     chan struct{}
+}
+
+// NewRelay builds a Relay from already-constructed backends (keyed by
+// account name, e.g. "matrix.myhome") and the gateway routes loaded from
+// config.
+func NewRelay(backends map[string]Bridger, gateways []Gateway) *Relay {
+	return &Relay{backends: backends, gateways: gateways, done: make(chan struct{})}
+}
+
+// Connect connects every configured backend and joins the channels its
+// gateway routes reference.
+func (r *Relay) Connect() error {
+	for account, b := range r.backends {
+		if err := b.Connect(); err != nil {
+			return fmt.Errorf("bridge: connect %s: %w", account, err)
+		}
+		for _, gw := range r.gateways {
+			for _, route := range gw.Routes {
+				if route.Account != account {
+					continue
+				}
+				if err := b.JoinChannel(route.Channel); err != nil {
+					return fmt.Errorf("bridge: join %s on %s: %w", route.Channel, account, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Disconnect tears down every backend. Errors are collected but do not stop
+// the rest of the backends from being disconnected. Done is closed once all
+// backends have been torn down, so pumpRemoteMessages loops know to stop
+// ranging over a Receive channel that itself is never closed.
+func (r *Relay) Disconnect() error {
+	var firstErr error
+	for account, b := range r.backends {
+		if err := b.Disconnect(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("bridge: disconnect %s: %w", account, err)
+		}
+	}
+	close(r.done)
+	return firstErr
+}
+
+// Done returns a channel that's closed once Disconnect has torn down every
+// backend, for callers ranging over a backend's Receive channel to select
+// on alongside it.
+func (r *Relay) Done() <-chan struct{} {
+	return r.done
+}
+
+// RelayFromWhatsApp fans a WhatsApp message out to every remote route
+// configured for the given WhatsApp chat JID.
+func (r *Relay) RelayFromWhatsApp(chatJID, username, text string) {
+	for _, gw := range r.gateways {
+		if gw.WhatsAppJID != chatJID {
+			continue
+		}
+		msg := Message{Username: username, Text: text, Account: "whatsapp"}
+		for _, route := range gw.Routes {
+			b, ok := r.backends[route.Account]
+			if !ok {
+				continue
+			}
+			msg.Channel = route.Channel
+			if err := b.Send(msg); err != nil {
+				fmt.Printf("bridge: failed to relay to %s/%s: %v\n", route.Account, route.Channel, err)
+			}
+		}
+	}
+}
+
+// Backend returns the configured Bridger for the given account name (e.g.
+// "matrix.myhome"), or nil if no such account is configured.
+func (r *Relay) Backend(account string) Bridger {
+	return r.backends[account]
+}
+
+// ToWhatsAppJID looks up which WhatsApp chat a remote (account, channel)
+// pair should be relayed into, for the reverse direction.
+func (r *Relay) ToWhatsAppJID(account, channel string) (string, bool) {
+	for _, gw := range r.gateways {
+		for _, route := range gw.Routes {
+			if route.Account == account && route.Channel == channel {
+				return gw.WhatsAppJID, true
+			}
+		}
+	}
+	return "", false
+}
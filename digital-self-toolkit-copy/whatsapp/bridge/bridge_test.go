@@ -0,0 +1,155 @@
+package bridge
+
+import "testing"
+
+// fakeBridger is a minimal Bridger used to observe what Relay sends it,
+// without needing a live backend connection.
+type fakeBridger struct {
+	connected bool
+	joined    []string
+	sent      []Message
+	recv      chan Message
+}
+
+func newFakeBridger() *fakeBridger {
+	return &fakeBridger{recv: make(chan Message, 1)}
+}
+
+func (f *fakeBridger) Connect() error { f.connected = true; return nil }
+func (f *fakeBridger) JoinChannel(channel string) error {
+	f.joined = append(f.joined, channel)
+	return nil
+}
+func (f *fakeBridger) Send(msg Message) error  { f.sent = append(f.sent, msg); return nil }
+func (f *fakeBridger) Receive() <-chan Message { return f.recv }
+func (f *fakeBridger) Disconnect() error       { return nil }
+
+func testGateways() []Gateway {
+	return []Gateway{
+		{
+			Name:        "family",
+			WhatsAppJID: "1234@g.us",
+			Routes: []Route{
+				{Account: "matrix.myhome", Channel: "!abc:example.org"},
+				{Account: "discord.main", Channel: "general"},
+			},
+		},
+	}
+}
+
+func TestConfigToGateways(t *testing.T) {
+	cfg := &Config{
+		Gateways: []GatewayConfig{
+			{
+				Name:        "family",
+				WhatsAppJID: "1234@g.us",
+				Routes: []RouteConfig{
+					{Account: "matrix.myhome", Channel: "!abc:example.org"},
+				},
+			},
+		},
+	}
+
+	gateways := cfg.toGateways()
+	if len(gateways) != 1 {
+		t.Fatalf("len(gateways) = %d, want 1", len(gateways))
+	}
+	gw := gateways[0]
+	if gw.Name != "family" || gw.WhatsAppJID != "1234@g.us" {
+		t.Errorf("gateway = %+v, want Name=family WhatsAppJID=1234@g.us", gw)
+	}
+	if len(gw.Routes) != 1 || gw.Routes[0].Account != "matrix.myhome" || gw.Routes[0].Channel != "!abc:example.org" {
+		t.Errorf("routes = %+v, want [{matrix.myhome !abc:example.org}]", gw.Routes)
+	}
+}
+
+func TestBuildRelayKeysBackendsByAccount(t *testing.T) {
+	cfg := &Config{
+		Matrix:  map[string]MatrixConfig{"myhome": {Server: "https://example.org"}},
+		Discord: map[string]DiscordConfig{"main": {Token: "tok"}},
+	}
+
+	relay, err := cfg.BuildRelay()
+	if err != nil {
+		t.Fatalf("BuildRelay() returned error: %v", err)
+	}
+	if relay.Backend("matrix.myhome") == nil {
+		t.Error("Backend(matrix.myhome) = nil, want a MatrixBridge")
+	}
+	if relay.Backend("discord.main") == nil {
+		t.Error("Backend(discord.main) = nil, want a DiscordBridge")
+	}
+	if relay.Backend("irc.nope") != nil {
+		t.Error("Backend(irc.nope) = non-nil, want nil for an unconfigured account")
+	}
+}
+
+func TestRelayFromWhatsApp(t *testing.T) {
+	matrixBackend := newFakeBridger()
+	discordBackend := newFakeBridger()
+	relay := NewRelay(map[string]Bridger{
+		"matrix.myhome": matrixBackend,
+		"discord.main":  discordBackend,
+	}, testGateways())
+
+	relay.RelayFromWhatsApp("1234@g.us", "Alice", "hello")
+
+	if len(matrixBackend.sent) != 1 || matrixBackend.sent[0].Text != "hello" || matrixBackend.sent[0].Channel != "!abc:example.org" {
+		t.Errorf("matrix backend got %+v, want one message with text=hello channel=!abc:example.org", matrixBackend.sent)
+	}
+	if len(discordBackend.sent) != 1 || discordBackend.sent[0].Channel != "general" {
+		t.Errorf("discord backend got %+v, want one message with channel=general", discordBackend.sent)
+	}
+}
+
+func TestRelayFromWhatsAppUnknownChat(t *testing.T) {
+	matrixBackend := newFakeBridger()
+	relay := NewRelay(map[string]Bridger{"matrix.myhome": matrixBackend}, testGateways())
+
+	relay.RelayFromWhatsApp("9999@g.us", "Alice", "hello")
+
+	if len(matrixBackend.sent) != 0 {
+		t.Errorf("sent = %+v, want no messages for an unconfigured chat JID", matrixBackend.sent)
+	}
+}
+
+func TestRelayToWhatsAppJID(t *testing.T) {
+	relay := NewRelay(nil, testGateways())
+
+	jid, ok := relay.ToWhatsAppJID("matrix.myhome", "!abc:example.org")
+	if !ok || jid != "1234@g.us" {
+		t.Errorf("ToWhatsAppJID(matrix.myhome, !abc:example.org) = (%q, %v), want (1234@g.us, true)", jid, ok)
+	}
+
+	if _, ok := relay.ToWhatsAppJID("matrix.myhome", "!other:example.org"); ok {
+		t.Error("ToWhatsAppJID for an unconfigured channel = true, want false")
+	}
+}
+
+func TestRelayConnectJoinsOnlyMatchingRoutes(t *testing.T) {
+	matrixBackend := newFakeBridger()
+	relay := NewRelay(map[string]Bridger{"matrix.myhome": matrixBackend}, testGateways())
+
+	if err := relay.Connect(); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	if !matrixBackend.connected {
+		t.Error("backend was not connected")
+	}
+	if len(matrixBackend.joined) != 1 || matrixBackend.joined[0] != "!abc:example.org" {
+		t.Errorf("joined = %v, want [!abc:example.org]", matrixBackend.joined)
+	}
+}
+
+func TestRelayDisconnectClosesDone(t *testing.T) {
+	relay := NewRelay(map[string]Bridger{"matrix.myhome": newFakeBridger()}, nil)
+
+	if err := relay.Disconnect(); err != nil {
+		t.Fatalf("Disconnect() returned error: %v", err)
+	}
+	select {
+	case <-relay.Done():
+	default:
+		t.Error("Done() channel not closed after Disconnect")
+	}
+}
@@ -0,0 +1,124 @@
+package bridge
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config mirrors the matterbridge-style TOML layout:
+//
+//	[whatsapp]
+//	session = "session.db"
+//
+//	[matrix.myhome]
+//	server = "https://matrix.example.org"
+//	username = "bridgebot"
+//	password = "..."
+//
+//	[[gateway]]
+//	name = "family"
+//	whatsapp-jid = "1234567890-123@g.us"
+//	  [[gateway.route]]
+//	  account = "matrix.myhome"
+//	  channel = "!abc123:example.org"
+type Config struct {
+	WhatsApp WhatsAppConfig           `toml:"whatsapp"`
+	Matrix   map[string]MatrixConfig  `toml:"matrix"`
+	Discord  map[string]DiscordConfig `toml:"discord"`
+	IRC      map[string]IRCConfig     `toml:"irc"`
+	Slack    map[string]SlackConfig   `toml:"slack"`
+	Gateways []GatewayConfig          `toml:"gateway"`
+}
+
+// WhatsAppConfig holds the settings for the local WhatsApp leg of the bridge.
+type WhatsAppConfig struct {
+	Session string `toml:"session"`
+}
+
+// MatrixConfig configures one Matrix backend account, named by its TOML
+// table key (e.g. "myhome" for [matrix.myhome]).
+type MatrixConfig struct {
+	Server   string `toml:"server"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+}
+
+// DiscordConfig configures one Discord backend account.
+type DiscordConfig struct {
+	Token   string `toml:"token"`
+	GuildID string `toml:"guild-id"`
+}
+
+// IRCConfig configures one IRC backend account.
+type IRCConfig struct {
+	Server   string `toml:"server"`
+	Nick     string `toml:"nick"`
+	UseTLS   bool   `toml:"use-tls"`
+	Password string `toml:"password"`
+}
+
+// SlackConfig configures one Slack backend account.
+type SlackConfig struct {
+	Token string `toml:"token"`
+}
+
+// GatewayConfig is the TOML form of a Gateway before its account names have
+// been resolved to live Bridger instances.
+type GatewayConfig struct {
+	Name        string        `toml:"name"`
+	WhatsAppJID string        `toml:"whatsapp-jid"`
+	Routes      []RouteConfig `toml:"route"`
+}
+
+// RouteConfig is the TOML form of a Route.
+type RouteConfig struct {
+	Account string `toml:"account"`
+	Channel string `toml:"channel"`
+}
+
+// LoadConfig reads and parses a bridge config file from path.
+func LoadConfig(path string) (*Config, error) {
+	var cfg Config
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return nil, fmt.Errorf("bridge: load config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// toGateways converts the parsed GatewayConfig entries into the Gateway form
+// used by Relay.
+func (c *Config) toGateways() []Gateway {
+	gateways := make([]Gateway, 0, len(c.Gateways))
+	for _, gc := range c.Gateways {
+		routes := make([]Route, 0, len(gc.Routes))
+		for _, rc := range gc.Routes {
+			routes = append(routes, Route{Account: rc.Account, Channel: rc.Channel})
+		}
+		gateways = append(gateways, Gateway{Name: gc.Name, WhatsAppJID: gc.WhatsAppJID, Routes: routes})
+	}
+	return gateways
+}
+
+// BuildRelay constructs a Relay from the parsed config, instantiating one
+// Bridger per configured backend account. Accounts are keyed as
+// "<protocol>.<name>" (e.g. "matrix.myhome") to match the route Account
+// field in [[gateway.route]].
+func (c *Config) BuildRelay() (*Relay, error) {
+	backends := make(map[string]Bridger)
+
+	for name, mc := range c.Matrix {
+		backends["matrix."+name] = NewMatrixBridge(mc)
+	}
+	for name, dc := range c.Discord {
+		backends["discord."+name] = NewDiscordBridge(dc)
+	}
+	for name, ic := range c.IRC {
+		backends["irc."+name] = NewIRCBridge(ic)
+	}
+	for name, sc := range c.Slack {
+		backends["slack."+name] = NewSlackBridge(sc)
+	}
+
+	return NewRelay(backends, c.toGateways()), nil
+}
@@ -0,0 +1,82 @@
+package bridge
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DiscordBridge is a Bridger backed by a Discord bot account.
+type DiscordBridge struct {
+	cfg     DiscordConfig
+	session *discordgo.Session
+	recv    chan Message
+	closing chan struct{}
+}
+
+// NewDiscordBridge constructs a DiscordBridge from its config. Connect must
+// be called before the backend is usable.
+func NewDiscordBridge(cfg DiscordConfig) *DiscordBridge {
+	return &DiscordBridge{cfg: cfg, recv: make(chan Message, 100), closing: make(chan struct{})}
+}
+
+// Connect opens the bot's Discord gateway session.
+func (d *DiscordBridge) Connect() error {
+	session, err := discordgo.New("Bot " + d.cfg.Token)
+	if err != nil {
+		return fmt.Errorf("discord: new session: %w", err)
+	}
+	session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.Author.ID == s.State.User.ID {
+			return
+		}
+		select {
+		case d.recv <- Message{
+			Channel:  m.ChannelID,
+			Username: m.Author.Username,
+			Text:     m.Content,
+			Account:  "discord",
+		}:
+		case <-d.closing:
+		}
+	})
+	if err := session.Open(); err != nil {
+		return fmt.Errorf("discord: open: %w", err)
+	}
+	d.session = session
+	return nil
+}
+
+// JoinChannel is a no-op for Discord: bots already see every channel their
+// guild membership grants, so "joining" just means we will relay to it.
+func (d *DiscordBridge) JoinChannel(channel string) error {
+	return nil
+}
+
+// Send posts a message into the Discord channel.
+func (d *DiscordBridge) Send(msg Message) error {
+	_, err := d.session.ChannelMessageSend(msg.Channel, fmt.Sprintf("**%s**: %s", msg.Username, msg.Text))
+	if err != nil {
+		return fmt.Errorf("discord: send to %s: %w", msg.Channel, err)
+	}
+	return nil
+}
+
+// Receive returns the channel of messages arriving from Discord.
+func (d *DiscordBridge) Receive() <-chan Message {
+	return d.recv
+}
+
+// Disconnect stops the gateway session. recv is deliberately never closed:
+// the handler goroutine that feeds it is driven by discordgo's own
+// dispatch loop, so we can't guarantee it has fully stopped before
+// returning, and sending on or closing recv concurrently would panic.
+// Closing closing first makes any in-flight handler invocation drop its
+// message instead of blocking or racing a closed channel.
+func (d *DiscordBridge) Disconnect() error {
+	close(d.closing)
+	if d.session == nil {
+		return nil
+	}
+	return d.session.Close()
+}
@@ -0,0 +1,104 @@
+package bridge
+
+import (
+	"fmt"
+
+	irc "github.com/lrstanley/girc"
+)
+
+// IRCBridge is a Bridger backed by a single IRC client connection.
+type IRCBridge struct {
+	cfg     IRCConfig
+	client  *irc.Client
+	recv    chan Message
+	errCh   chan error
+	closing chan struct{}
+}
+
+// NewIRCBridge constructs an IRCBridge from its config. Connect must be
+// called before the backend is usable.
+func NewIRCBridge(cfg IRCConfig) *IRCBridge {
+	return &IRCBridge{
+		cfg:     cfg,
+		recv:    make(chan Message, 100),
+		errCh:   make(chan error, 1),
+		closing: make(chan struct{}),
+	}
+}
+
+// Connect registers on the configured IRC server and starts the read loop
+// that feeds Receive. It blocks until registration (RPL_WELCOME) completes
+// or the connection fails, so callers like Relay.Connect can safely
+// JoinChannel immediately after Connect returns.
+func (b *IRCBridge) Connect() error {
+	client := irc.New(irc.Config{
+		Server:     b.cfg.Server,
+		Nick:       b.cfg.Nick,
+		User:       b.cfg.Nick,
+		Name:       b.cfg.Nick,
+		SSL:        b.cfg.UseTLS,
+		ServerPass: b.cfg.Password,
+	})
+
+	ready := make(chan struct{}, 1)
+	client.Handlers.Add(irc.RPL_WELCOME, func(c *irc.Client, e irc.Event) {
+		select {
+		case ready <- struct{}{}:
+		default:
+		}
+	})
+	client.Handlers.Add(irc.PRIVMSG, func(c *irc.Client, e irc.Event) {
+		if len(e.Params) < 2 {
+			return
+		}
+		select {
+		case b.recv <- Message{
+			Channel:  e.Params[0],
+			Username: e.Source.Name,
+			Text:     e.Last(),
+			Account:  "irc",
+		}:
+		case <-b.closing:
+		}
+	})
+	b.client = client
+
+	go func() {
+		b.errCh <- client.Connect()
+	}()
+
+	select {
+	case <-ready:
+		return nil
+	case err := <-b.errCh:
+		return fmt.Errorf("irc: connect: %w", err)
+	}
+}
+
+// JoinChannel joins the given IRC channel.
+func (b *IRCBridge) JoinChannel(channel string) error {
+	b.client.Cmd.Join(channel)
+	return nil
+}
+
+// Send writes a PRIVMSG to the IRC channel, prefixed with the relayed
+// sender's name since IRC has no native concept of a relay identity.
+func (b *IRCBridge) Send(msg Message) error {
+	b.client.Cmd.Message(msg.Channel, fmt.Sprintf("<%s> %s", msg.Username, msg.Text))
+	return nil
+}
+
+// Receive returns the channel of messages arriving from IRC.
+func (b *IRCBridge) Receive() <-chan Message {
+	return b.recv
+}
+
+// Disconnect closes the IRC connection. recv is deliberately never closed:
+// the PRIVMSG handler runs on girc's own read loop, which may still be
+// delivering an event when Close is called; closing b.closing first makes
+// that handler drop its message instead of racing a closed recv.
+func (b *IRCBridge) Disconnect() error {
+	close(b.closing)
+	b.client.Close()
+	return <-b.errCh
+}
@@ -0,0 +1,115 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"maunium.net/go/mautrix"
+	"maunium.net/go/mautrix/id"
+)
+
+// MatrixBridge is a Bridger backed by a Matrix homeserver account.
+type MatrixBridge struct {
+	cfg     MatrixConfig
+	client  *mautrix.Client
+	rooms   map[string]id.RoomID
+	recv    chan Message
+	closing chan struct{}
+}
+
+// NewMatrixBridge constructs a MatrixBridge from its config. Connect must be
+// called before the backend is usable.
+func NewMatrixBridge(cfg MatrixConfig) *MatrixBridge {
+	return &MatrixBridge{
+		cfg:     cfg,
+		rooms:   make(map[string]id.RoomID),
+		recv:    make(chan Message, 100),
+		closing: make(chan struct{}),
+	}
+}
+
+// Connect logs in to the homeserver and starts the sync loop that feeds
+// Receive.
+func (m *MatrixBridge) Connect() error {
+	client, err := mautrix.NewClient(m.cfg.Server, "", "")
+	if err != nil {
+		return fmt.Errorf("matrix: new client: %w", err)
+	}
+	resp, err := client.Login(context.Background(), &mautrix.ReqLogin{
+		Type:             mautrix.AuthTypePassword,
+		Identifier:       mautrix.UserIdentifier{Type: mautrix.IdentifierTypeUser, User: m.cfg.Username},
+		Password:         m.cfg.Password,
+		StoreCredentials: true,
+	})
+	if err != nil {
+		return fmt.Errorf("matrix: login: %w", err)
+	}
+	client.SetCredentials(resp.UserID, resp.AccessToken)
+	m.client = client
+
+	syncer := client.Syncer.(*mautrix.DefaultSyncer)
+	syncer.OnEventType("m.room.message", func(_ mautrix.EventSource, evt *mautrix.Event) {
+		if evt.Sender.String() == resp.UserID.String() {
+			return
+		}
+		body, _ := evt.Content.Raw["body"].(string)
+		select {
+		case m.recv <- Message{
+			Channel:  evt.RoomID.String(),
+			Username: evt.Sender.String(),
+			Text:     body,
+			Account:  "matrix",
+		}:
+		case <-m.closing:
+		}
+	})
+
+	go func() {
+		if err := client.Sync(); err != nil {
+			fmt.Printf("matrix: sync stopped: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// JoinChannel joins the Matrix room with the given room ID or alias.
+func (m *MatrixBridge) JoinChannel(channel string) error {
+	resp, err := m.client.JoinRoom(context.Background(), channel, "", nil)
+	if err != nil {
+		return fmt.Errorf("matrix: join %s: %w", channel, err)
+	}
+	m.rooms[channel] = resp.RoomID
+	return nil
+}
+
+// Send posts a message into the Matrix room, prefixing it with the
+// originating WhatsApp sender's name so it reads like a relayed message.
+func (m *MatrixBridge) Send(msg Message) error {
+	roomID, ok := m.rooms[msg.Channel]
+	if !ok {
+		roomID = id.RoomID(msg.Channel)
+	}
+	_, err := m.client.SendText(context.Background(), roomID, fmt.Sprintf("%s: %s", msg.Username, msg.Text))
+	if err != nil {
+		return fmt.Errorf("matrix: send to %s: %w", msg.Channel, err)
+	}
+	return nil
+}
+
+// Receive returns the channel of messages arriving from Matrix.
+func (m *MatrixBridge) Receive() <-chan Message {
+	return m.recv
+}
+
+// Disconnect stops the sync loop. recv is deliberately never closed: StopSync
+// only signals the sync loop to stop between syncs, so an OnEventType
+// callback can still be in flight when Disconnect returns. Closing closing
+// makes any such callback drop its message instead of racing a closed recv.
+func (m *MatrixBridge) Disconnect() error {
+	if m.client != nil {
+		m.client.StopSync()
+	}
+	close(m.closing)
+	return nil
+}
@@ -0,0 +1,117 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// SlackBridge is a Bridger backed by a Slack app using Socket Mode, so no
+// public webhook endpoint is required.
+type SlackBridge struct {
+	cfg    SlackConfig
+	api    *slack.Client
+	socket *socketmode.Client
+	recv   chan Message
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSlackBridge constructs a SlackBridge from its config. Connect must be
+// called before the backend is usable.
+func NewSlackBridge(cfg SlackConfig) *SlackBridge {
+	return &SlackBridge{cfg: cfg, recv: make(chan Message, 100), done: make(chan struct{})}
+}
+
+// Connect opens the Socket Mode connection and starts the event loop that
+// feeds Receive.
+func (s *SlackBridge) Connect() error {
+	api := slack.New(s.cfg.Token)
+	socket := socketmode.New(api)
+	s.api = api
+	s.socket = socket
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go func() {
+		defer close(s.done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-socket.Events:
+				if !ok {
+					return
+				}
+				if evt.Type != socketmode.EventTypeEventsAPI {
+					continue
+				}
+				eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+				if !ok {
+					continue
+				}
+				socket.Ack(*evt.Request)
+				inner, ok := eventsAPIEvent.InnerEvent.Data.(*slackevents.MessageEvent)
+				if !ok || inner.BotID != "" {
+					continue
+				}
+				select {
+				case s.recv <- Message{
+					Channel:  inner.Channel,
+					Username: inner.User,
+					Text:     inner.Text,
+					Account:  "slack",
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		if err := socket.RunContext(ctx); err != nil && ctx.Err() == nil {
+			fmt.Printf("slack: socket mode stopped: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// JoinChannel is a no-op for Slack: the app must already be invited to the
+// channel from the Slack side, so "joining" just means we will relay to it.
+func (s *SlackBridge) JoinChannel(channel string) error {
+	return nil
+}
+
+// Send posts a message into the Slack channel.
+func (s *SlackBridge) Send(msg Message) error {
+	_, _, err := s.api.PostMessage(msg.Channel,
+		slack.MsgOptionText(fmt.Sprintf("*%s*: %s", msg.Username, msg.Text), false))
+	if err != nil {
+		return fmt.Errorf("slack: send to %s: %w", msg.Channel, err)
+	}
+	return nil
+}
+
+// Receive returns the channel of messages arriving from Slack.
+func (s *SlackBridge) Receive() <-chan Message {
+	return s.recv
+}
+
+// Disconnect stops the Socket Mode event loop and waits for it to exit
+// before returning, so no send on recv can race a later close. recv itself
+// is never closed, since Receive's caller ranges over it and a send-after-
+// close would panic if Disconnect and delivery ever overlapped.
+func (s *SlackBridge) Disconnect() error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	<-s.done
+	return nil
+}
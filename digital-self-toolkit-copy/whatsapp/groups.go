@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// joinedGroupsMu guards joinedGroups against concurrent access from the
+// event handler and the startup sync.
+var joinedGroupsMu sync.Mutex
+
+// joinedGroups resolves a group JID to its metadata so eventHandler can
+// show a real group name instead of the raw JID user part.
+var joinedGroups = make(map[types.JID]*types.GroupInfo)
+
+// syncJoinedGroups fetches the current set of joined groups from the
+// server, populates joinedGroups, and persists a snapshot to disk.
+func syncJoinedGroups(ctx context.Context) error {
+	groups, err := client.GetJoinedGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("groups: get joined groups: %w", err)
+	}
+
+	joinedGroupsMu.Lock()
+	for _, g := range groups {
+		joinedGroups[g.JID] = g
+	}
+	joinedGroupsMu.Unlock()
+
+	return persistJoinedGroups()
+}
+
+// persistJoinedGroups writes the current joined-groups map, including
+// participant lists, to data/groups.json.
+func persistJoinedGroups() error {
+	joinedGroupsMu.Lock()
+	snapshot := make(map[string]*types.GroupInfo, len(joinedGroups))
+	for jid, g := range joinedGroups {
+		snapshot[jid.String()] = g
+	}
+	joinedGroupsMu.Unlock()
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("groups: marshal snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll("data", 0755); err != nil {
+		return fmt.Errorf("groups: create data dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join("data", "groups.json"), data, 0644); err != nil {
+		return fmt.Errorf("groups: write snapshot: %w", err)
+	}
+	return nil
+}
+
+// resolveChatName returns the known group name for chat, falling back to
+// fallback (typically the JID's user part) if the group isn't known yet.
+func resolveChatName(chat types.JID, fallback string) string {
+	joinedGroupsMu.Lock()
+	defer joinedGroupsMu.Unlock()
+	if g, ok := joinedGroups[chat]; ok && g.Name != "" {
+		return g.Name
+	}
+	return fallback
+}
+
+// recordSystemEvent appends a synthetic MessageData entry so join/leave/
+// topic changes show up in the JSON export alongside real messages,
+// turning it into a complete audit log of chat activity.
+func recordSystemEvent(chatJID, chatName, text string, ts time.Time) {
+	entry := MessageData{
+		ID:          encodeMsgID(chatJID, "system", fmt.Sprintf("system-%d", ts.UnixNano())),
+		Timestamp:   ts,
+		ChatJID:     chatJID,
+		ChatName:    chatName,
+		MessageType: "system",
+		Text:        text,
+		IsGroup:     true,
+	}
+
+	if err := store.Save(entry); err != nil {
+		log.Printf("Failed to persist system event for %s: %v", chatJID, err)
+	}
+
+	fmt.Printf("System event in %s: %s\n", chatName, text)
+}
+
+// handleGroupInfo reacts to group membership, topic, and promotion changes,
+// updates joinedGroups, and records a system event for each change so the
+// export captures a complete audit trail.
+func handleGroupInfo(evt *events.GroupInfo) {
+	chatJID := evt.JID.String()
+	name := resolveChatName(evt.JID, evt.JID.User)
+
+	if evt.Name != nil {
+		joinedGroupsMu.Lock()
+		if g, ok := joinedGroups[evt.JID]; ok {
+			g.Name = evt.Name.Name
+		}
+		joinedGroupsMu.Unlock()
+		name = evt.Name.Name
+		recordSystemEvent(chatJID, name, fmt.Sprintf("Group renamed to %q by %s", evt.Name.Name, evt.Sender), evt.Timestamp)
+	}
+
+	if evt.Topic != nil {
+		recordSystemEvent(chatJID, name, fmt.Sprintf("Topic changed to %q by %s", evt.Topic.Topic, evt.Sender), evt.Timestamp)
+	}
+
+	for _, jid := range evt.Join {
+		recordSystemEvent(chatJID, name, fmt.Sprintf("%s joined the group", jid), evt.Timestamp)
+	}
+	for _, jid := range evt.Leave {
+		recordSystemEvent(chatJID, name, fmt.Sprintf("%s left the group", jid), evt.Timestamp)
+	}
+	for _, jid := range evt.Promote {
+		recordSystemEvent(chatJID, name, fmt.Sprintf("%s was promoted to admin", jid), evt.Timestamp)
+	}
+	for _, jid := range evt.Demote {
+		recordSystemEvent(chatJID, name, fmt.Sprintf("%s was demoted from admin", jid), evt.Timestamp)
+	}
+
+	if err := persistJoinedGroups(); err != nil {
+		log.Printf("groups: failed to persist snapshot: %v", err)
+	}
+}
+
+// contactsMu guards contactNames against concurrent access from the event
+// handler and the message handler's name resolution.
+var contactsMu sync.Mutex
+
+// contactNames caches the latest known display name for a JID, so messages
+// can show a real name even on events (e.g. a PushName-less group message)
+// that don't carry one themselves.
+var contactNames = make(map[types.JID]string)
+
+// resolveContactName returns the cached display name for jid, falling back
+// to fallback if no contact update for jid has been seen yet.
+func resolveContactName(jid types.JID, fallback string) string {
+	contactsMu.Lock()
+	defer contactsMu.Unlock()
+	if name, ok := contactNames[jid]; ok && name != "" {
+		return name
+	}
+	return fallback
+}
+
+// handleContact updates the local contact record when WhatsApp pushes a
+// contact change (name edit, new contact, etc.), so future messages from
+// that JID resolve to the latest display name via resolveContactName.
+func handleContact(evt *events.Contact) {
+	name := evt.Action.GetFullName()
+	if name == "" {
+		log.Printf("Contact updated: %s (no name set)", evt.JID)
+		return
+	}
+	contactsMu.Lock()
+	contactNames[evt.JID] = name
+	contactsMu.Unlock()
+	log.Printf("Contact updated: %s (name: %s)", evt.JID, name)
+}
@@ -2,23 +2,36 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 	"time"
 
-	"github.com/mdp/qrterminal"
+	_ "github.com/mattn/go-sqlite3"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	waLog "go.mau.fi/whatsmeow/util/log"
-	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/oliviazhg/two-voices-one-brain/digital-self-toolkit-copy/whatsapp/bridge"
+	"github.com/oliviazhg/two-voices-one-brain/digital-self-toolkit-copy/whatsapp/sender"
 )
 
+var bridgeConfigPath = flag.String("bridge-config", "", "path to a TOML bridge config; when set, relays messages to/from Matrix/Discord/IRC/Slack")
+var sendAPIAddr = flag.String("send-api-addr", "", "address to serve the outbound send/react/revoke/edit HTTP API on, e.g. :8080; disabled if empty")
+var storeKind = flag.String("store", "json", "message persistence backend: json, jsonl, or sqlite")
+var storePath = flag.String("store-path", "", "directory (jsonl) or file (sqlite) the store writes to; defaults to data/")
+var since = flag.Bool("since", false, "log the store's most recently stored message timestamp as the resume point; no history sync is requested, the backlog is what WhatsApp delivers on connect; requires -store sqlite, since jsonl/json only append and can't dedupe resent history")
+
+var relay *bridge.Relay
+
 // MessageData represents a WhatsApp message for JSON export
 type MessageData struct {
 	ID          string    `json:"id"`
@@ -31,10 +44,19 @@ type MessageData struct {
 	Text        string    `json:"text"`
 	IsFromMe    bool      `json:"is_from_me"`
 	IsGroup     bool      `json:"is_group"`
+	Media       *Media    `json:"media,omitempty"`
+
+	// QuotedID, QuotedSender, and QuotedText describe the message this one
+	// replies to, if any. QuotedID is encoded as <chatJID>/<senderJID>/<msgID>
+	// so it can be round-tripped back into a reply ContextInfo.
+	QuotedID      string   `json:"quoted_id,omitempty"`
+	QuotedSender  string   `json:"quoted_sender,omitempty"`
+	QuotedText    string   `json:"quoted_text,omitempty"`
+	MentionedJIDs []string `json:"mentioned_jids,omitempty"`
 }
 
-var messages []MessageData
 var client *whatsmeow.Client
+var store Store
 
 func eventHandler(evt interface{}) {
 	switch v := evt.(type) {
@@ -48,6 +70,8 @@ func eventHandler(evt interface{}) {
 			IsFromMe:  v.Info.IsFromMe,
 			IsGroup:   v.Info.IsGroup,
 		}
+		msg.ID = encodeMsgID(msg.ChatJID, msg.FromJID, v.Info.ID)
+		applyReplyContext(&msg, msg.ChatJID, v.Message)
 
 		// Get sender name
 		if v.Info.IsFromMe {
@@ -57,69 +81,105 @@ func eventHandler(evt interface{}) {
 			if v.Info.PushName != "" {
 				msg.FromName = v.Info.PushName
 			} else {
-				msg.FromName = v.Info.Sender.User
+				msg.FromName = resolveContactName(v.Info.Sender, v.Info.Sender.User)
 			}
 		} else {
 			// Direct message - try to get contact name
 			if v.Info.PushName != "" {
 				msg.FromName = v.Info.PushName
 			} else {
-				msg.FromName = v.Info.Sender.User
+				msg.FromName = resolveContactName(v.Info.Sender, v.Info.Sender.User)
 			}
 		}
 
 		// Get chat name
 		if v.Info.IsGroup {
-			// For groups, we'll use the JID for now
-			msg.ChatName = v.Info.Chat.User
+			msg.ChatName = resolveChatName(v.Info.Chat, v.Info.Chat.User)
 		} else {
 			msg.ChatName = msg.FromName
 		}
 
 		// Extract message content
+		var dl whatsmeow.DownloadableMessage
+		var mimetype string
 		if v.Message.GetConversation() != "" {
 			msg.MessageType = "text"
 			msg.Text = v.Message.GetConversation()
 		} else if v.Message.GetExtendedTextMessage() != nil {
 			msg.MessageType = "extended_text"
 			msg.Text = v.Message.GetExtendedTextMessage().GetText()
-		} else if v.Message.GetImageMessage() != nil {
+		} else if img := v.Message.GetImageMessage(); img != nil {
 			msg.MessageType = "image"
-			msg.Text = v.Message.GetImageMessage().GetCaption()
-		} else if v.Message.GetVideoMessage() != nil {
+			msg.Text = img.GetCaption()
+			dl, mimetype = img, img.GetMimetype()
+		} else if vid := v.Message.GetVideoMessage(); vid != nil {
 			msg.MessageType = "video"
-			msg.Text = v.Message.GetVideoMessage().GetCaption()
-		} else if v.Message.GetAudioMessage() != nil {
+			msg.Text = vid.GetCaption()
+			dl, mimetype = vid, vid.GetMimetype()
+		} else if aud := v.Message.GetAudioMessage(); aud != nil {
 			msg.MessageType = "audio"
 			msg.Text = "[Audio Message]"
-		} else if v.Message.GetDocumentMessage() != nil {
+			dl, mimetype = aud, aud.GetMimetype()
+		} else if doc := v.Message.GetDocumentMessage(); doc != nil {
 			msg.MessageType = "document"
-			msg.Text = fmt.Sprintf("[Document: %s]", v.Message.GetDocumentMessage().GetTitle())
+			msg.Text = fmt.Sprintf("[Document: %s]", doc.GetTitle())
+			dl, mimetype = doc, doc.GetMimetype()
+		} else if sticker := v.Message.GetStickerMessage(); sticker != nil {
+			msg.MessageType = "sticker"
+			msg.Text = "[Sticker]"
+			dl, mimetype = sticker, sticker.GetMimetype()
 		} else {
 			msg.MessageType = "other"
 			msg.Text = "[Unsupported message type]"
 		}
 
-		messages = append(messages, msg)
+		if err := store.Save(msg); err != nil {
+			log.Printf("Failed to persist message %s: %v", msg.ID, err)
+		}
+		if dl != nil {
+			enqueueMediaDownload(msg.ID, msg.ChatJID, dl, mimetype)
+		}
 		fmt.Printf("New message from %s in %s: %s\n", msg.FromName, msg.ChatName, msg.Text)
 
+		if relay != nil && msg.Text != "" && !msg.IsFromMe {
+			relay.RelayFromWhatsApp(msg.ChatJID, msg.FromName, msg.Text)
+		}
+
 	case *events.Receipt:
 		// Handle message receipts (read, delivered, etc.)
 		fmt.Printf("Receipt: %s for %s\n", v.Type, v.MessageIDs)
+
+	case *events.GroupInfo:
+		handleGroupInfo(v)
+
+	case *events.Contact:
+		handleContact(v)
 	}
 }
 
 func main() {
+	flag.Parse()
+
 	// Create logs directory if it doesn't exist
 	os.MkdirAll("logs", 0755)
-	
+
+	if *since && *storeKind != "sqlite" {
+		log.Fatalf("-since requires -store sqlite: jsonl and json stores only append, so resuming would re-save messages WhatsApp resends as duplicates")
+	}
+
+	s, err := newStore(*storeKind, *storePath)
+	if err != nil {
+		log.Fatalf("Failed to set up store: %v", err)
+	}
+	store = s
+
 	// Setup logging
 	dbLog := waLog.Stdout("Database", "INFO", true)
 	clientLog := waLog.Stdout("Client", "INFO", true)
 
 	// Create database store
 	ctx := context.Background()
-	container, err := sqlstore.New(ctx, "sqlite3", "file:session.db?_foreign_keys=on", dbLog)
+	container, err := sqlstore.New(ctx, "sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on", sessionDBPath()), dbLog)
 	if err != nil {
 		log.Fatalf("Failed to create database: %v", err)
 	}
@@ -133,28 +193,15 @@ func main() {
 	// Create client
 	client = whatsmeow.NewClient(deviceStore, clientLog)
 	client.AddEventHandler(eventHandler)
+	client.AddEventHandler(reconnectHandler)
+
+	startMediaWorkers()
 
 	// Connect to WhatsApp
 	if client.Store.ID == nil {
 		// No previous session, need to pair
-		qrChan, _ := client.GetQRChannel(context.Background())
-		err = client.Connect()
-		if err != nil {
-			log.Fatalf("Failed to connect: %v", err)
-		}
-
-		for evt := range qrChan {
-			if evt.Event == "code" {
-				fmt.Println("QR Code:")
-				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
-				fmt.Println("Scan this QR code with your WhatsApp mobile app")
-			} else {
-				fmt.Printf("QR channel result: %s\n", evt.Event)
-				if evt.Event == "success" {
-					fmt.Println("Successfully paired!")
-					break
-				}
-			}
+		if err := pairWithQR(); err != nil {
+			log.Fatalf("Failed to pair: %v", err)
 		}
 	} else {
 		// Previous session exists, just connect
@@ -165,51 +212,148 @@ func main() {
 		fmt.Println("Connected to WhatsApp!")
 	}
 
+	if err := syncJoinedGroups(context.Background()); err != nil {
+		log.Printf("Failed to sync joined groups: %v", err)
+	}
+
+	if *since {
+		maxTS, err := store.MaxTimestamp()
+		if err != nil {
+			log.Printf("Failed to read stored high-water mark: %v", err)
+		} else {
+			requestHistorySync(maxTS)
+		}
+	}
+
+	if *bridgeConfigPath != "" {
+		if err := setupBridge(*bridgeConfigPath); err != nil {
+			log.Fatalf("Failed to set up bridge: %v", err)
+		}
+	}
+
+	if *sendAPIAddr != "" {
+		startSendAPI(*sendAPIAddr)
+	}
+
 	// Setup graceful shutdown
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	fmt.Println("WhatsApp message extractor is running...")
 	fmt.Println("Send some messages to see them appear here.")
-	fmt.Println("Press Ctrl+C to stop and save messages to JSON file.")
+	fmt.Println("Press Ctrl+C to stop.")
 
 	// Wait for interrupt signal
 	<-c
 
-	// Save messages to JSON file
-	saveMessagesToJSON()
+	if err := store.Close(); err != nil {
+		log.Printf("Failed to close store: %v", err)
+	}
+
+	if relay != nil {
+		relay.Disconnect()
+	}
 
 	// Disconnect
 	client.Disconnect()
 	fmt.Println("Disconnected from WhatsApp")
 }
 
-func saveMessagesToJSON() {
-	if len(messages) == 0 {
-		fmt.Println("No messages to save")
-		return
+// sessionDBPath returns the whatsmeow session database path: the bridge
+// config's [whatsapp] session setting if a bridge config is configured and
+// sets one, otherwise the "session.db" default.
+func sessionDBPath() string {
+	if *bridgeConfigPath == "" {
+		return "session.db"
 	}
+	cfg, err := bridge.LoadConfig(*bridgeConfigPath)
+	if err != nil || cfg.WhatsApp.Session == "" {
+		return "session.db"
+	}
+	return cfg.WhatsApp.Session
+}
 
-	// Create data directory if it doesn't exist
-	os.MkdirAll("data", 0755)
-
-	// Create filename with timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename := filepath.Join("data", fmt.Sprintf("whatsapp_messages_%s.json", timestamp))
-
-	// Convert messages to JSON
-	jsonData, err := json.MarshalIndent(messages, "", "  ")
+// setupBridge loads the TOML bridge config, builds the configured backends,
+// connects them, and starts the goroutines that relay remote messages back
+// into WhatsApp via client.SendMessage.
+func setupBridge(path string) error {
+	cfg, err := bridge.LoadConfig(path)
 	if err != nil {
-		log.Printf("Failed to marshal messages to JSON: %v", err)
-		return
+		return err
 	}
 
-	// Write to file
-	err = os.WriteFile(filename, jsonData, 0644)
+	r, err := cfg.BuildRelay()
 	if err != nil {
-		log.Printf("Failed to write messages to file: %v", err)
+		return err
+	}
+	if err := r.Connect(); err != nil {
+		return err
+	}
+	relay = r
+
+	for name := range cfg.Matrix {
+		go pumpRemoteMessages(r, "matrix."+name)
+	}
+	for name := range cfg.Discord {
+		go pumpRemoteMessages(r, "discord."+name)
+	}
+	for name := range cfg.IRC {
+		go pumpRemoteMessages(r, "irc."+name)
+	}
+	for name := range cfg.Slack {
+		go pumpRemoteMessages(r, "slack."+name)
+	}
+
+	fmt.Println("Bridge relay started")
+	return nil
+}
+
+// startSendAPI mounts the outbound send/react/revoke/edit HTTP API
+// alongside the extractor and serves it in the background.
+func startSendAPI(addr string) {
+	mux := http.NewServeMux()
+	sender.RegisterHandlers(mux, sender.New(client))
+
+	go func() {
+		log.Printf("Send API listening on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("Send API stopped: %v", err)
+		}
+	}()
+}
+
+// pumpRemoteMessages forwards messages arriving on a single backend account
+// into the WhatsApp chat its gateway route maps to. It selects on r.Done
+// rather than relying on b.Receive() closing, since backends never close
+// that channel (see Bridger.Receive).
+func pumpRemoteMessages(r *bridge.Relay, account string) {
+	b := r.Backend(account)
+	if b == nil {
 		return
 	}
+	for {
+		var msg bridge.Message
+		select {
+		case msg = <-b.Receive():
+		case <-r.Done():
+			return
+		}
 
-	fmt.Printf("Saved %d messages to %s\n", len(messages), filename)
-} 
\ No newline at end of file
+		chatJID, ok := r.ToWhatsAppJID(account, msg.Channel)
+		if !ok {
+			continue
+		}
+		jid, err := types.ParseJID(chatJID)
+		if err != nil {
+			log.Printf("bridge: invalid WhatsApp JID %s: %v", chatJID, err)
+			continue
+		}
+		text := fmt.Sprintf("[%s] %s: %s", msg.Account, msg.Username, msg.Text)
+		_, err = client.SendMessage(context.Background(), jid, &waE2E.Message{
+			Conversation: proto.String(text),
+		})
+		if err != nil {
+			log.Printf("bridge: failed to send to WhatsApp chat %s: %v", chatJID, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+)
+
+// Media describes a downloaded and archived media attachment.
+type Media struct {
+	Path     string `json:"path"`
+	SHA256   string `json:"sha256"`
+	MimeType string `json:"mime_type"`
+}
+
+// mediaJob is one unit of work for the media worker pool: a downloadable
+// message plus enough context to place the result on disk and find the
+// MessageData entry it belongs to.
+type mediaJob struct {
+	msgID    string
+	chatJID  string
+	dl       whatsmeow.DownloadableMessage
+	mimetype string
+}
+
+const mediaWorkerCount = 4
+
+var mediaJobs = make(chan mediaJob, 64)
+
+// enqueueMediaDownload schedules a media download for a just-received
+// message without blocking the event loop. If the worker pool is saturated,
+// the job is dropped rather than risking an unbounded backlog.
+func enqueueMediaDownload(msgID, chatJID string, dl whatsmeow.DownloadableMessage, mimetype string) {
+	job := mediaJob{msgID: msgID, chatJID: chatJID, dl: dl, mimetype: mimetype}
+	select {
+	case mediaJobs <- job:
+	default:
+		log.Printf("media: worker pool saturated, dropping download for %s", msgID)
+	}
+}
+
+// startMediaWorkers launches the bounded pool of goroutines that download
+// media so a burst of attachments does not block the event loop.
+func startMediaWorkers() {
+	for i := 0; i < mediaWorkerCount; i++ {
+		go mediaWorker()
+	}
+}
+
+func mediaWorker() {
+	for job := range mediaJobs {
+		media, err := downloadAndArchiveMedia(job)
+		if err != nil {
+			log.Printf("media: failed to download %s: %v", job.msgID, err)
+			continue
+		}
+		if err := store.UpdateMedia(job.msgID, media); err != nil {
+			log.Printf("media: failed to attach media to %s: %v", job.msgID, err)
+		}
+	}
+}
+
+// downloadAndArchiveMedia downloads the message's media, writes it to
+// data/media/<chat>/<messageID>.<ext>, and returns the archived location
+// plus its checksum and mimetype. It retries with backoff on
+// whatsmeow.ErrMediaDownloadFailed.
+func downloadAndArchiveMedia(job mediaJob) (*Media, error) {
+	var data []byte
+	var err error
+
+	backoff := time.Second
+	for attempt := 0; attempt < 3; attempt++ {
+		data, err = client.Download(context.Background(), job.dl)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, whatsmeow.ErrMediaDownloadFailed) {
+			return nil, err
+		}
+		if attempt == 2 {
+			break
+		}
+		log.Printf("media: download failed for %s (attempt %d), retrying in %s: %v", job.msgID, attempt+1, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join("data", "media", sanitizeFilename(job.chatJID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("media: create dir %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, sanitizeFilename(job.msgID)+extensionForMimeType(job.mimetype))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("media: write %s: %w", path, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return &Media{
+		Path:     path,
+		SHA256:   hex.EncodeToString(sum[:]),
+		MimeType: job.mimetype,
+	}, nil
+}
+
+// extensionForMimeType resolves a file extension from a MIME type,
+// stripping any codec parameters (e.g. "audio/ogg; codecs=opus").
+func extensionForMimeType(mimetype string) string {
+	base := strings.SplitN(mimetype, ";", 2)[0]
+	exts, err := mime.ExtensionsByType(base)
+	if err != nil || len(exts) == 0 {
+		return ".bin"
+	}
+	return exts[0]
+}
+
+// sanitizeFilename makes a JID safe to use as a path component.
+func sanitizeFilename(jid string) string {
+	replacer := strings.NewReplacer("@", "_at_", ":", "_", "/", "_")
+	return replacer.Replace(jid)
+}
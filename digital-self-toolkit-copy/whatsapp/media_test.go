@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestExtensionForMimeType(t *testing.T) {
+	tests := []struct {
+		mimetype string
+		want     string
+	}{
+		{"image/jpeg", ".jpe"},
+		{"image/png", ".png"},
+		{"audio/ogg; codecs=opus", ".oga"},
+		{"application/octet-stream", ".bin"},
+		{"not-a-real-mimetype", ".bin"},
+		{"", ".bin"},
+	}
+
+	for _, tt := range tests {
+		if got := extensionForMimeType(tt.mimetype); got != tt.want {
+			t.Errorf("extensionForMimeType(%q) = %q, want %q", tt.mimetype, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		jid  string
+		want string
+	}{
+		{"123456789@s.whatsapp.net", "123456789_at_s.whatsapp.net"},
+		{"123@g.us/456@s.whatsapp.net/ABCDEF", "123_at_g.us_456_at_s.whatsapp.net_ABCDEF"},
+		{"plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeFilename(tt.jid); got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.jid, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mdp/qrterminal"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 5 * time.Minute
+)
+
+// reconnecting is true while a reconnectLoop is active, so a flapping
+// connection can't spawn multiple concurrent backoff loops all calling
+// client.Connect() independently.
+var reconnecting atomic.Bool
+
+// reconnectHandler watches for connection-lifecycle events and keeps the
+// client alive across network blips and WhatsApp server restarts. It is
+// registered alongside eventHandler via client.AddEventHandler.
+func reconnectHandler(evt interface{}) {
+	switch v := evt.(type) {
+	case *events.Disconnected:
+		log.Printf("reconnect: disconnected, reconnecting")
+		startReconnect()
+
+	case *events.StreamReplaced:
+		log.Printf("reconnect: session replaced by another connection, reconnecting")
+		startReconnect()
+
+	case *events.TemporaryBan:
+		log.Printf("reconnect: temporarily banned (%s), waiting %s before retrying", v.Code, v.Expire)
+		if !reconnecting.CompareAndSwap(false, true) {
+			log.Printf("reconnect: already in progress, ignoring")
+			return
+		}
+		go func() {
+			defer reconnecting.Store(false)
+			time.Sleep(v.Expire)
+			reconnectLoop()
+		}()
+
+	case *events.LoggedOut:
+		log.Printf("reconnect: logged out (reason: %s), re-pairing", v.Reason)
+		go func() {
+			if err := pairWithQR(); err != nil {
+				log.Printf("reconnect: re-pair failed: %v", err)
+			}
+		}()
+	}
+}
+
+// startReconnect launches reconnectLoop in the background unless one is
+// already running.
+func startReconnect() {
+	if !reconnecting.CompareAndSwap(false, true) {
+		log.Printf("reconnect: already in progress, ignoring")
+		return
+	}
+	go func() {
+		defer reconnecting.Store(false)
+		reconnectLoop()
+	}()
+}
+
+// reconnectLoop retries client.Connect with jittered exponential backoff
+// (1s..5m) until it succeeds; every connect error is logged and retried,
+// benign transient ones included. It is not used for LoggedOut, which requires a fresh QR
+// pairing instead of a plain reconnect.
+func reconnectLoop() {
+	backoff := minReconnectBackoff
+	for {
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		if wait < minReconnectBackoff {
+			wait = minReconnectBackoff
+		}
+		log.Printf("reconnect: waiting %s before reconnect attempt", wait)
+		time.Sleep(wait)
+
+		err := client.Connect()
+		if err == nil {
+			log.Printf("reconnect: reconnected successfully")
+			return
+		}
+		if isBenignConnectError(err) {
+			log.Printf("reconnect: benign connect error, will retry: %v", err)
+		} else {
+			log.Printf("reconnect: connect failed, will retry: %v", err)
+		}
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+// isBenignConnectError reports whether err is a known transient condition
+// such as a closed connection. reconnectLoop retries on every error and uses
+// this only to pick its log message.
+func isBenignConnectError(err error) bool {
+	msg := err.Error()
+	for _, benign := range []string{"connection closed", "context deadline exceeded", "websocket: close"} {
+		if strings.Contains(msg, benign) {
+			return true
+		}
+	}
+	return false
+}
+
+// pairWithQR runs the QR pairing flow, printing the code to the terminal
+// and connecting once the user scans it. It is used both for the first-run
+// pairing and to re-pair after a LoggedOut event.
+func pairWithQR() error {
+	qrChan, _ := client.GetQRChannel(context.Background())
+	if err := client.Connect(); err != nil {
+		return fmt.Errorf("pair: connect: %w", err)
+	}
+
+	for evt := range qrChan {
+		if evt.Event == "code" {
+			fmt.Println("QR Code:")
+			qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
+			fmt.Println("Scan this QR code with your WhatsApp mobile app")
+		} else {
+			fmt.Printf("QR channel result: %s\n", evt.Event)
+			if evt.Event == "success" {
+				fmt.Println("Successfully paired!")
+				break
+			}
+		}
+	}
+	return nil
+}
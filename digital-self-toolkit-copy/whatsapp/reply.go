@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+// encodeMsgID packs a chat JID, sender JID, and raw message ID into the
+// single string WhatsApp needs to construct a reply: the StanzaID alone is
+// not enough, the sender (Participant) JID is also required.
+func encodeMsgID(chatJID, senderJID, msgID string) string {
+	return fmt.Sprintf("%s/%s/%s", chatJID, senderJID, msgID)
+}
+
+// contextInfoOf returns the ContextInfo carried by whichever message type is
+// actually set, since WhatsApp attaches reply/mention metadata per-type
+// rather than on a shared envelope.
+func contextInfoOf(m *waE2E.Message) *waE2E.ContextInfo {
+	switch {
+	case m.GetExtendedTextMessage() != nil:
+		return m.GetExtendedTextMessage().GetContextInfo()
+	case m.GetImageMessage() != nil:
+		return m.GetImageMessage().GetContextInfo()
+	case m.GetVideoMessage() != nil:
+		return m.GetVideoMessage().GetContextInfo()
+	case m.GetAudioMessage() != nil:
+		return m.GetAudioMessage().GetContextInfo()
+	case m.GetDocumentMessage() != nil:
+		return m.GetDocumentMessage().GetContextInfo()
+	case m.GetStickerMessage() != nil:
+		return m.GetStickerMessage().GetContextInfo()
+	default:
+		return nil
+	}
+}
+
+// applyReplyContext populates the quote/mention fields on msg from the
+// message's ContextInfo, if it has any.
+func applyReplyContext(msg *MessageData, chatJID string, m *waE2E.Message) {
+	ctx := contextInfoOf(m)
+	if ctx == nil {
+		return
+	}
+	msg.MentionedJIDs = ctx.GetMentionedJID()
+	if ctx.GetStanzaID() == "" {
+		return
+	}
+	msg.QuotedID = encodeMsgID(chatJID, ctx.GetParticipant(), ctx.GetStanzaID())
+	msg.QuotedSender = ctx.GetParticipant()
+	msg.QuotedText = quotedText(ctx.GetQuotedMessage())
+}
+
+// quotedText pulls a best-effort text preview out of a quoted message,
+// since the quote can be any message type.
+func quotedText(qm *waE2E.Message) string {
+	if qm == nil {
+		return ""
+	}
+	if qm.GetConversation() != "" {
+		return qm.GetConversation()
+	}
+	if qm.GetExtendedTextMessage() != nil {
+		return qm.GetExtendedTextMessage().GetText()
+	}
+	if qm.GetImageMessage() != nil {
+		return qm.GetImageMessage().GetCaption()
+	}
+	if qm.GetVideoMessage() != nil {
+		return qm.GetVideoMessage().GetCaption()
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEncodeMsgID(t *testing.T) {
+	got := encodeMsgID("123@g.us", "456@s.whatsapp.net", "ABCDEF")
+	want := "123@g.us/456@s.whatsapp.net/ABCDEF"
+	if got != want {
+		t.Errorf("encodeMsgID() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyReplyContext(t *testing.T) {
+	ctx := &waE2E.ContextInfo{
+		StanzaID:    proto.String("ORIGID"),
+		Participant: proto.String("456@s.whatsapp.net"),
+		QuotedMessage: &waE2E.Message{
+			Conversation: proto.String("original text"),
+		},
+		MentionedJID: []string{"789@s.whatsapp.net"},
+	}
+	m := &waE2E.Message{
+		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String("replying"),
+			ContextInfo: ctx,
+		},
+	}
+
+	var msg MessageData
+	applyReplyContext(&msg, "123@g.us", m)
+
+	if want := "123@g.us/456@s.whatsapp.net/ORIGID"; msg.QuotedID != want {
+		t.Errorf("QuotedID = %q, want %q", msg.QuotedID, want)
+	}
+	if want := "456@s.whatsapp.net"; msg.QuotedSender != want {
+		t.Errorf("QuotedSender = %q, want %q", msg.QuotedSender, want)
+	}
+	if want := "original text"; msg.QuotedText != want {
+		t.Errorf("QuotedText = %q, want %q", msg.QuotedText, want)
+	}
+	if len(msg.MentionedJIDs) != 1 || msg.MentionedJIDs[0] != "789@s.whatsapp.net" {
+		t.Errorf("MentionedJIDs = %v, want [789@s.whatsapp.net]", msg.MentionedJIDs)
+	}
+}
+
+func TestApplyReplyContextNoReply(t *testing.T) {
+	m := &waE2E.Message{Conversation: proto.String("just a plain message")}
+
+	var msg MessageData
+	applyReplyContext(&msg, "123@g.us", m)
+
+	if msg.QuotedID != "" {
+		t.Errorf("QuotedID = %q, want empty for a non-reply message", msg.QuotedID)
+	}
+}
+
+func TestQuotedText(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *waE2E.Message
+		want string
+	}{
+		{"nil message", nil, ""},
+		{"conversation", &waE2E.Message{Conversation: proto.String("hi")}, "hi"},
+		{
+			"extended text",
+			&waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String("hi there")}},
+			"hi there",
+		},
+		{
+			"image caption",
+			&waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String("a photo")}},
+			"a photo",
+		},
+		{"unsupported type", &waE2E.Message{StickerMessage: &waE2E.StickerMessage{}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quotedText(tt.msg); got != tt.want {
+				t.Errorf("quotedText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package sender
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// sendRequest is the body for POST /send. Exactly one of Text or Media
+// should be set. ReplyToText, if set alongside ReplyTo, becomes the quoted
+// message's preview text; callers normally have this on hand already, since
+// it's the Text the extractor reported for the message being replied to.
+type sendRequest struct {
+	Chat        string        `json:"chat"`
+	Text        string        `json:"text,omitempty"`
+	ReplyTo     string        `json:"reply_to,omitempty"`
+	ReplyToText string        `json:"reply_to_text,omitempty"`
+	Media       *mediaRequest `json:"media,omitempty"`
+}
+
+type mediaRequest struct {
+	Path    string `json:"path"`
+	Caption string `json:"caption,omitempty"`
+	Kind    string `json:"kind"`
+}
+
+type reactRequest struct {
+	MsgRef string `json:"msg_ref"`
+	Emoji  string `json:"emoji"`
+}
+
+type revokeRequest struct {
+	MsgRef string `json:"msg_ref"`
+}
+
+type editRequest struct {
+	MsgRef string `json:"msg_ref"`
+	Text   string `json:"text"`
+}
+
+// RegisterHandlers mounts the send/react/revoke/edit HTTP API on mux.
+func RegisterHandlers(mux *http.ServeMux, s *Sender) {
+	mux.HandleFunc("/send", s.handleSend)
+	mux.HandleFunc("/react", s.handleReact)
+	mux.HandleFunc("/revoke", s.handleRevoke)
+	mux.HandleFunc("/edit", s.handleEdit)
+}
+
+func (s *Sender) handleSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req sendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	chat, err := types.ParseJID(req.Chat)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var replyTo *ReplyRef
+	if req.ReplyTo != "" {
+		ref, err := ParseMsgRef(req.ReplyTo)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ref.Text = req.ReplyToText
+		replyTo = &ref
+	}
+
+	var resp interface{}
+	if req.Media != nil {
+		resp, err = s.SendMedia(chat, req.Media.Path, req.Media.Caption, req.Media.Kind)
+	} else {
+		resp, err = s.SendText(chat, req.Text, replyTo)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Sender) handleReact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req reactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ref, err := ParseMsgRef(req.MsgRef)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := s.React(ref, req.Emoji)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Sender) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req revokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ref, err := ParseMsgRef(req.MsgRef)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := s.Revoke(ref)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Sender) handleEdit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req editRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ref, err := ParseMsgRef(req.MsgRef)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := s.Edit(ref, req.Text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
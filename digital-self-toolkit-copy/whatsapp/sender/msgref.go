@@ -0,0 +1,56 @@
+package sender
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// MsgRef identifies a specific message well enough to act on it: WhatsApp
+// requires the sender's JID (not just the message ID) to build a reply,
+// reaction, edit, or revoke. ReplyRef is an alias for the same shape, used
+// when a MsgRef is being passed as the target of a reply rather than the
+// subject of an action.
+type MsgRef struct {
+	Chat   types.JID
+	Sender types.JID
+	ID     string
+
+	// Text is the quoted message's body, used only when the ref is passed as
+	// a ReplyRef to populate the native quote-preview bubble. It is not part
+	// of the encoded wire form: ParseMsgRef/String never set or read it.
+	Text string
+}
+
+// ReplyRef is the reply-target form of MsgRef.
+type ReplyRef = MsgRef
+
+// ParseMsgRef decodes the "<chatJID>/<senderJID>/<msgID>" form produced by
+// the extractor side (see MessageData.ID / QuotedID) back into a MsgRef.
+func ParseMsgRef(ref string) (MsgRef, error) {
+	parts := strings.SplitN(ref, "/", 3)
+	if len(parts) != 3 {
+		return MsgRef{}, fmt.Errorf("sender: malformed message ref %q, want chatJID/senderJID/msgID", ref)
+	}
+	chat, err := types.ParseJID(parts[0])
+	if err == nil && chat.User == "" {
+		err = fmt.Errorf("missing user part")
+	}
+	if err != nil {
+		return MsgRef{}, fmt.Errorf("sender: invalid chat JID in ref %q: %w", ref, err)
+	}
+	senderJID, err := types.ParseJID(parts[1])
+	if err == nil && senderJID.User == "" {
+		err = fmt.Errorf("missing user part")
+	}
+	if err != nil {
+		return MsgRef{}, fmt.Errorf("sender: invalid sender JID in ref %q: %w", ref, err)
+	}
+	return MsgRef{Chat: chat, Sender: senderJID, ID: parts[2]}, nil
+}
+
+// String encodes the MsgRef back into "<chatJID>/<senderJID>/<msgID>" form.
+func (r MsgRef) String() string {
+	return fmt.Sprintf("%s/%s/%s", r.Chat.String(), r.Sender.String(), r.ID)
+}
@@ -0,0 +1,41 @@
+package sender
+
+import "testing"
+
+func TestParseMsgRefRoundTrip(t *testing.T) {
+	ref := "123@g.us/456@s.whatsapp.net/ABCDEF"
+
+	parsed, err := ParseMsgRef(ref)
+	if err != nil {
+		t.Fatalf("ParseMsgRef(%q) returned error: %v", ref, err)
+	}
+	if got := parsed.Chat.String(); got != "123@g.us" {
+		t.Errorf("Chat = %q, want %q", got, "123@g.us")
+	}
+	if got := parsed.Sender.String(); got != "456@s.whatsapp.net" {
+		t.Errorf("Sender = %q, want %q", got, "456@s.whatsapp.net")
+	}
+	if parsed.ID != "ABCDEF" {
+		t.Errorf("ID = %q, want %q", parsed.ID, "ABCDEF")
+	}
+
+	if got := parsed.String(); got != ref {
+		t.Errorf("String() = %q, want %q", got, ref)
+	}
+}
+
+func TestParseMsgRefMalformed(t *testing.T) {
+	tests := []string{
+		"",
+		"justonepart",
+		"only/two",
+		"bad-jid/456@s.whatsapp.net/ABCDEF",
+		"123@g.us/bad-jid/ABCDEF",
+	}
+
+	for _, ref := range tests {
+		if _, err := ParseMsgRef(ref); err == nil {
+			t.Errorf("ParseMsgRef(%q) = nil error, want an error", ref)
+		}
+	}
+}
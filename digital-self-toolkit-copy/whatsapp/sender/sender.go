@@ -0,0 +1,216 @@
+// Package sender turns the read-only extractor into a full-duplex client:
+// it wraps a *whatsmeow.Client with the outbound operations (send, react,
+// edit, revoke) needed to drive WhatsApp from automations.
+package sender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// Sender wraps a whatsmeow client with the outbound message operations.
+type Sender struct {
+	Client *whatsmeow.Client
+}
+
+// New constructs a Sender around an already-connected whatsmeow client.
+func New(client *whatsmeow.Client) *Sender {
+	return &Sender{Client: client}
+}
+
+// SendText sends a plain text message, optionally as a reply. When replyTo
+// is non-nil, the message is composed with a ContextInfo so WhatsApp
+// renders it as a native quoted reply.
+func (s *Sender) SendText(chat types.JID, text string, replyTo *ReplyRef) (whatsmeow.SendResponse, error) {
+	message := &waE2E.Message{
+		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String(text),
+			ContextInfo: contextInfoFor(replyTo),
+		},
+	}
+	resp, err := s.Client.SendMessage(context.Background(), chat, message)
+	if err != nil {
+		return resp, fmt.Errorf("sender: send text to %s: %w", chat, err)
+	}
+	return resp, nil
+}
+
+// SendMedia uploads the file at path and sends it as a media message of the
+// given kind ("image", "video", "audio", or "document"), with the caption
+// attached where the media type supports one.
+func (s *Sender) SendMedia(chat types.JID, path, caption, kind string) (whatsmeow.SendResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return whatsmeow.SendResponse{}, fmt.Errorf("sender: read %s: %w", path, err)
+	}
+
+	mediaType, mimetype, err := mediaTypeFor(kind)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	uploaded, err := s.Client.Upload(context.Background(), data, mediaType)
+	if err != nil {
+		return whatsmeow.SendResponse{}, fmt.Errorf("sender: upload %s: %w", path, err)
+	}
+
+	message, err := mediaMessageFor(kind, caption, mimetype, uploaded, uint64(len(data)))
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	resp, err := s.Client.SendMessage(context.Background(), chat, message)
+	if err != nil {
+		return resp, fmt.Errorf("sender: send media to %s: %w", chat, err)
+	}
+	return resp, nil
+}
+
+// React sends (or clears, if emoji is "") an emoji reaction to a message.
+func (s *Sender) React(ref MsgRef, emoji string) (whatsmeow.SendResponse, error) {
+	message := &waE2E.Message{
+		ReactionMessage: &waE2E.ReactionMessage{
+			Key: &waE2E.MessageKey{
+				RemoteJID:   proto.String(ref.Chat.String()),
+				FromMe:      proto.Bool(isOwnJID(s.Client.Store.ID, ref.Sender)),
+				ID:          proto.String(ref.ID),
+				Participant: proto.String(ref.Sender.String()),
+			},
+			Text:              proto.String(emoji),
+			SenderTimestampMS: proto.Int64(time.Now().UnixMilli()),
+		},
+	}
+	resp, err := s.Client.SendMessage(context.Background(), ref.Chat, message)
+	if err != nil {
+		return resp, fmt.Errorf("sender: react to %s: %w", ref, err)
+	}
+	return resp, nil
+}
+
+// isOwnJID reports whether sender is the same account as own, so the
+// MessageKey built for a reaction (or similar) correctly marks FromMe. own
+// is nil if the client hasn't finished pairing, in which case nothing can
+// be "ours" yet.
+func isOwnJID(own *types.JID, sender types.JID) bool {
+	if own == nil {
+		return false
+	}
+	return sender.ToNonAD() == own.ToNonAD()
+}
+
+// Edit replaces the text of a previously sent message.
+func (s *Sender) Edit(ref MsgRef, newText string) (whatsmeow.SendResponse, error) {
+	edited := &waE2E.Message{
+		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text: proto.String(newText),
+		},
+	}
+	resp, err := s.Client.SendMessage(context.Background(), ref.Chat, s.Client.BuildEdit(ref.Chat, ref.ID, edited))
+	if err != nil {
+		return resp, fmt.Errorf("sender: edit %s: %w", ref, err)
+	}
+	return resp, nil
+}
+
+// Revoke deletes a previously sent message for everyone.
+func (s *Sender) Revoke(ref MsgRef) (whatsmeow.SendResponse, error) {
+	resp, err := s.Client.SendMessage(context.Background(), ref.Chat, s.Client.BuildRevoke(ref.Chat, ref.Sender, ref.ID))
+	if err != nil {
+		return resp, fmt.Errorf("sender: revoke %s: %w", ref, err)
+	}
+	return resp, nil
+}
+
+// contextInfoFor builds the ContextInfo needed to render a message as a
+// reply to ref, or nil if ref is nil. ref.Text becomes the quoted message's
+// body, so WhatsApp shows a real quote-preview bubble rather than a blank
+// one.
+func contextInfoFor(ref *ReplyRef) *waE2E.ContextInfo {
+	if ref == nil {
+		return nil
+	}
+	return &waE2E.ContextInfo{
+		StanzaID:    proto.String(ref.ID),
+		Participant: proto.String(ref.Sender.String()),
+		QuotedMessage: &waE2E.Message{
+			Conversation: proto.String(ref.Text),
+		},
+	}
+}
+
+// mediaTypeFor maps a kind string to the whatsmeow upload media type and the
+// fixed mimetype SendMedia sends for that kind; the file's actual content
+// type is not inspected.
+func mediaTypeFor(kind string) (whatsmeow.MediaType, string, error) {
+	switch kind {
+	case "image":
+		return whatsmeow.MediaImage, "image/jpeg", nil
+	case "video":
+		return whatsmeow.MediaVideo, "video/mp4", nil
+	case "audio":
+		return whatsmeow.MediaAudio, "audio/ogg; codecs=opus", nil
+	case "document":
+		return whatsmeow.MediaDocument, "application/octet-stream", nil
+	default:
+		return 0, "", fmt.Errorf("sender: unknown media kind %q", kind)
+	}
+}
+
+// mediaMessageFor builds the proto message for an uploaded attachment,
+// populating the fields WhatsApp needs to fetch and decrypt it.
+func mediaMessageFor(kind, caption, mimetype string, uploaded whatsmeow.UploadResponse, fileLength uint64) (*waE2E.Message, error) {
+	switch kind {
+	case "image":
+		return &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
+			Caption:       proto.String(caption),
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(fileLength),
+		}}, nil
+	case "video":
+		return &waE2E.Message{VideoMessage: &waE2E.VideoMessage{
+			Caption:       proto.String(caption),
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(fileLength),
+		}}, nil
+	case "audio":
+		return &waE2E.Message{AudioMessage: &waE2E.AudioMessage{
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(fileLength),
+		}}, nil
+	case "document":
+		return &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+			Caption:       proto.String(caption),
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(fileLength),
+		}}, nil
+	default:
+		return nil, fmt.Errorf("sender: unknown media kind %q", kind)
+	}
+}
@@ -0,0 +1,109 @@
+package sender
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestContextInfoForNil(t *testing.T) {
+	if got := contextInfoFor(nil); got != nil {
+		t.Errorf("contextInfoFor(nil) = %+v, want nil", got)
+	}
+}
+
+func TestContextInfoForPopulatesQuote(t *testing.T) {
+	sender, err := types.ParseJID("456@s.whatsapp.net")
+	if err != nil {
+		t.Fatalf("ParseJID: %v", err)
+	}
+	ref := &ReplyRef{Sender: sender, ID: "ORIGID", Text: "original text"}
+
+	ctx := contextInfoFor(ref)
+	if ctx.GetStanzaID() != "ORIGID" {
+		t.Errorf("StanzaID = %q, want ORIGID", ctx.GetStanzaID())
+	}
+	if ctx.GetParticipant() != "456@s.whatsapp.net" {
+		t.Errorf("Participant = %q, want 456@s.whatsapp.net", ctx.GetParticipant())
+	}
+	if got := ctx.GetQuotedMessage().GetConversation(); got != "original text" {
+		t.Errorf("QuotedMessage.Conversation = %q, want %q", got, "original text")
+	}
+}
+
+func TestMediaTypeFor(t *testing.T) {
+	tests := []struct {
+		kind         string
+		wantType     whatsmeow.MediaType
+		wantMimetype string
+		wantErr      bool
+	}{
+		{"image", whatsmeow.MediaImage, "image/jpeg", false},
+		{"video", whatsmeow.MediaVideo, "video/mp4", false},
+		{"audio", whatsmeow.MediaAudio, "audio/ogg; codecs=opus", false},
+		{"document", whatsmeow.MediaDocument, "application/octet-stream", false},
+		{"bogus", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		gotType, gotMimetype, err := mediaTypeFor(tt.kind)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("mediaTypeFor(%q) = nil error, want an error", tt.kind)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("mediaTypeFor(%q) returned error: %v", tt.kind, err)
+			continue
+		}
+		if gotType != tt.wantType || gotMimetype != tt.wantMimetype {
+			t.Errorf("mediaTypeFor(%q) = (%v, %q), want (%v, %q)", tt.kind, gotType, gotMimetype, tt.wantType, tt.wantMimetype)
+		}
+	}
+}
+
+func TestMediaMessageFor(t *testing.T) {
+	uploaded := whatsmeow.UploadResponse{URL: "https://example.org/media", DirectPath: "/v/t/abc"}
+
+	tests := []struct {
+		kind string
+	}{{"image"}, {"video"}, {"audio"}, {"document"}}
+
+	for _, tt := range tests {
+		msg, err := mediaMessageFor(tt.kind, "a caption", "application/octet-stream", uploaded, 42)
+		if err != nil {
+			t.Errorf("mediaMessageFor(%q) returned error: %v", tt.kind, err)
+			continue
+		}
+		if msg == nil {
+			t.Errorf("mediaMessageFor(%q) = nil message", tt.kind)
+		}
+	}
+
+	if _, err := mediaMessageFor("bogus", "", "", uploaded, 0); err == nil {
+		t.Error("mediaMessageFor(bogus) = nil error, want an error")
+	}
+}
+
+func TestIsOwnJID(t *testing.T) {
+	self, err := types.ParseJID("111@s.whatsapp.net")
+	if err != nil {
+		t.Fatalf("ParseJID: %v", err)
+	}
+	other, err := types.ParseJID("222@s.whatsapp.net")
+	if err != nil {
+		t.Fatalf("ParseJID: %v", err)
+	}
+
+	if !isOwnJID(&self, self) {
+		t.Error("isOwnJID(self, self) = false, want true")
+	}
+	if isOwnJID(&self, other) {
+		t.Error("isOwnJID(self, other) = true, want false")
+	}
+	if isOwnJID(nil, self) {
+		t.Error("isOwnJID(nil, self) = true, want false before pairing")
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store is implemented by every message persistence backend. The jsonl and
+// sqlite stores durably write msg before Save returns, so the process can be
+// killed at any point without losing the message just received;
+// jsonExportStore keeps messages in memory and writes them only on Close.
+type Store interface {
+	// Save persists msg.
+	Save(msg MessageData) error
+	// UpdateMedia attaches media metadata to an already-saved message, once
+	// its download completes asynchronously.
+	UpdateMedia(msgID string, media *Media) error
+	// MaxTimestamp returns the timestamp of the most recently stored
+	// message, or the zero time if the store is empty.
+	MaxTimestamp() (time.Time, error)
+	// Close flushes and releases any resources held by the store.
+	Close() error
+}
+
+// newStore builds the Store named by kind, rooted at path. kind is one of
+// "jsonl", "sqlite", or "json" (the original batch-export behavior, kept as
+// one Store implementation among the others).
+func newStore(kind, path string) (Store, error) {
+	switch kind {
+	case "jsonl":
+		return newJSONLStore(path)
+	case "sqlite":
+		return newSQLiteStore(path)
+	case "json", "":
+		return newJSONExportStore(), nil
+	default:
+		return nil, fmt.Errorf("store: unknown store kind %q", kind)
+	}
+}
+
+// requestHistorySync logs the high-water mark a -since resume is starting
+// from. whatsmeow does not expose a public "resume since timestamp" call:
+// history backlog is delivered automatically via its own HistorySync
+// protocol during the initial handshake after Connect, and WhatsApp may
+// resend messages at or before since. Only sqliteStore dedupes those
+// (INSERT OR REPLACE keyed on message ID); jsonlStore and jsonExportStore
+// are plain appends, so main() refuses -since unless -store is sqlite.
+func requestHistorySync(since time.Time) {
+	if since.IsZero() {
+		log.Printf("history sync: no stored messages yet, starting fresh")
+		return
+	}
+	log.Printf("history sync: resuming from %s; relying on WhatsApp's own backlog delivery and store dedup", since.Format(time.RFC3339))
+}
+
+// --- JSON export store (original behavior) ---------------------------------
+
+// jsonExportStore buffers messages in memory and writes them all out as a
+// single timestamped JSON file on Close, matching the extractor's original
+// Ctrl+C behavior.
+type jsonExportStore struct {
+	mu       sync.Mutex
+	messages []MessageData
+}
+
+func newJSONExportStore() *jsonExportStore {
+	return &jsonExportStore{}
+}
+
+func (s *jsonExportStore) Save(msg MessageData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func (s *jsonExportStore) UpdateMedia(msgID string, media *Media) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.messages {
+		if s.messages[i].ID == msgID {
+			s.messages[i].Media = media
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *jsonExportStore) MaxTimestamp() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var max time.Time
+	for _, msg := range s.messages {
+		if msg.Timestamp.After(max) {
+			max = msg.Timestamp
+		}
+	}
+	return max, nil
+}
+
+func (s *jsonExportStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.messages) == 0 {
+		fmt.Println("No messages to save")
+		return nil
+	}
+
+	if err := os.MkdirAll("data", 0755); err != nil {
+		return fmt.Errorf("store: create data dir: %w", err)
+	}
+
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	filename := filepath.Join("data", fmt.Sprintf("whatsapp_messages_%s.json", timestamp))
+
+	jsonData, err := json.MarshalIndent(s.messages, "", "  ")
+	if err != nil {
+		return fmt.Errorf("store: marshal messages: %w", err)
+	}
+
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("store: write %s: %w", filename, err)
+	}
+
+	fmt.Printf("Saved %d messages to %s\n", len(s.messages), filename)
+	return nil
+}
+
+// --- JSONL store -------------------------------------------------------
+
+// jsonlStore appends one JSON object per line to data/messages-YYYY-MM-DD.jsonl,
+// rotating to a new file at local midnight so no single file grows
+// unbounded.
+type jsonlStore struct {
+	mu  sync.Mutex
+	dir string
+
+	day  string
+	file *os.File
+}
+
+func newJSONLStore(dir string) (*jsonlStore, error) {
+	if dir == "" {
+		dir = "data"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("store: create dir %s: %w", dir, err)
+	}
+	return &jsonlStore{dir: dir}, nil
+}
+
+func (s *jsonlStore) rotateLocked() error {
+	day := time.Now().Format("2006-01-02")
+	if s.file != nil && s.day == day {
+		return nil
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("messages-%s.jsonl", day))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("store: open %s: %w", path, err)
+	}
+	s.file = f
+	s.day = day
+	return nil
+}
+
+func (s *jsonlStore) appendLocked(msg MessageData) error {
+	if err := s.rotateLocked(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("store: marshal message: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("store: write message: %w", err)
+	}
+	return s.file.Sync()
+}
+
+func (s *jsonlStore) Save(msg MessageData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendLocked(msg)
+}
+
+// UpdateMedia appends a media-update record rather than mutating history,
+// since JSONL is append-only; downstream readers apply it by scanning
+// forward and overlaying onto the original record by ID.
+func (s *jsonlStore) UpdateMedia(msgID string, media *Media) error {
+	update := MessageData{
+		ID:          msgID,
+		Timestamp:   time.Now(),
+		MessageType: "media-update",
+		Media:       media,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendLocked(update)
+}
+
+func (s *jsonlStore) MaxTimestamp() (time.Time, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "messages-*.jsonl"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("store: glob %s: %w", s.dir, err)
+	}
+	sort.Strings(files)
+
+	var max time.Time
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("store: open %s: %w", path, err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var msg MessageData
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				continue
+			}
+			if msg.MessageType == "media-update" {
+				continue
+			}
+			if msg.Timestamp.After(max) {
+				max = msg.Timestamp
+			}
+		}
+		f.Close()
+	}
+	return max, nil
+}
+
+func (s *jsonlStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// --- SQLite store --------------------------------------------------------
+
+// sqliteStore writes each message into a single messages table using the
+// already-imported mattn/go-sqlite3 driver.
+type sqliteStore struct {
+	mu sync.Mutex
+	db *sql.DB
+}
+
+func newSQLiteStore(path string) (*sqliteStore, error) {
+	if path == "" {
+		path = "data/messages.db"
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("store: create dir %s: %w", dir, err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on", path))
+	if err != nil {
+		return nil, fmt.Errorf("store: open %s: %w", path, err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS messages (
+	id TEXT PRIMARY KEY,
+	ts INTEGER NOT NULL,
+	chat_jid TEXT NOT NULL,
+	from_jid TEXT NOT NULL,
+	message_type TEXT NOT NULL,
+	raw BLOB NOT NULL
+)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: create schema: %w", err)
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Save(msg MessageData) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("store: marshal message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.db.Exec(
+		`INSERT OR REPLACE INTO messages (id, ts, chat_jid, from_jid, message_type, raw) VALUES (?, ?, ?, ?, ?, ?)`,
+		msg.ID, msg.Timestamp.Unix(), msg.ChatJID, msg.FromJID, msg.MessageType, raw,
+	)
+	if err != nil {
+		return fmt.Errorf("store: insert message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) UpdateMedia(msgID string, media *Media) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var raw []byte
+	err := s.db.QueryRow(`SELECT raw FROM messages WHERE id = ?`, msgID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store: read message %s: %w", msgID, err)
+	}
+
+	var msg MessageData
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return fmt.Errorf("store: unmarshal message %s: %w", msgID, err)
+	}
+	msg.Media = media
+
+	updated, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("store: marshal message %s: %w", msgID, err)
+	}
+
+	_, err = s.db.Exec(`UPDATE messages SET raw = ? WHERE id = ?`, updated, msgID)
+	if err != nil {
+		return fmt.Errorf("store: update message %s: %w", msgID, err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) MaxTimestamp() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ts sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(ts) FROM messages`).Scan(&ts); err != nil {
+		return time.Time{}, fmt.Errorf("store: query max timestamp: %w", err)
+	}
+	if !ts.Valid {
+		return time.Time{}, nil
+	}
+	return time.Unix(ts.Int64, 0), nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
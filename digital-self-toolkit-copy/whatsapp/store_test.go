@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// exerciseStore runs the same Save/UpdateMedia/MaxTimestamp sequence against
+// any Store implementation, so each backend is checked against one shared
+// contract.
+func exerciseStore(t *testing.T, s Store) {
+	t.Helper()
+
+	t1 := time.Now().Add(-time.Hour).Truncate(time.Second)
+	t2 := time.Now().Truncate(time.Second)
+
+	if err := s.Save(MessageData{ID: "msg-1", Timestamp: t1, MessageType: "text", Text: "hello"}); err != nil {
+		t.Fatalf("Save(msg-1) returned error: %v", err)
+	}
+	if err := s.Save(MessageData{ID: "msg-2", Timestamp: t2, MessageType: "text", Text: "world"}); err != nil {
+		t.Fatalf("Save(msg-2) returned error: %v", err)
+	}
+
+	max, err := s.MaxTimestamp()
+	if err != nil {
+		t.Fatalf("MaxTimestamp() returned error: %v", err)
+	}
+	if !max.Equal(t2) {
+		t.Errorf("MaxTimestamp() = %v, want %v", max, t2)
+	}
+
+	media := &Media{Path: "data/media/x.jpg", SHA256: "deadbeef", MimeType: "image/jpeg"}
+	if err := s.UpdateMedia("msg-1", media); err != nil {
+		t.Fatalf("UpdateMedia(msg-1) returned error: %v", err)
+	}
+	// UpdateMedia on an unknown ID must not error: the json and sqlite stores
+	// ignore it, and the jsonl store appends the update record as it does for
+	// any ID.
+	if err := s.UpdateMedia("no-such-message", media); err != nil {
+		t.Errorf("UpdateMedia(no-such-message) returned error: %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+}
+
+func TestJSONExportStore(t *testing.T) {
+	exerciseStore(t, newJSONExportStore())
+}
+
+func TestJSONLStore(t *testing.T) {
+	s, err := newJSONLStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("newJSONLStore() returned error: %v", err)
+	}
+	exerciseStore(t, s)
+}
+
+func TestSQLiteStore(t *testing.T) {
+	s, err := newSQLiteStore(filepath.Join(t.TempDir(), "messages.db"))
+	if err != nil {
+		t.Fatalf("newSQLiteStore() returned error: %v", err)
+	}
+	exerciseStore(t, s)
+}
+
+func TestSQLiteStoreSaveDedupesByID(t *testing.T) {
+	s, err := newSQLiteStore(filepath.Join(t.TempDir(), "messages.db"))
+	if err != nil {
+		t.Fatalf("newSQLiteStore() returned error: %v", err)
+	}
+	defer s.Close()
+
+	ts := time.Now().Truncate(time.Second)
+	if err := s.Save(MessageData{ID: "dup", Timestamp: ts, Text: "first"}); err != nil {
+		t.Fatalf("Save(first) returned error: %v", err)
+	}
+	if err := s.Save(MessageData{ID: "dup", Timestamp: ts, Text: "resent"}); err != nil {
+		t.Fatalf("Save(resent) returned error: %v", err)
+	}
+
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE id = ?`, "dup").Scan(&count); err != nil {
+		t.Fatalf("query count: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("messages with id=dup = %d, want 1 (INSERT OR REPLACE should dedupe)", count)
+	}
+}